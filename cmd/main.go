@@ -6,19 +6,22 @@ import (
 	"github.com/Zhwt/csfutil/utils"
 	"os"
 	"strconv"
+	"strings"
 )
 
 var usageMessages = map[string]string{
-	"export": `Usage: csfutil export <filename.csf> [output.xlsx]
+	"export": `Usage: csfutil export [-by-category] <filename.csf> [output.xlsx]
 
 Exports all CSF LabelValue items inside the given CSF file into a spreadsheet. The exported file will have the following structure:
 
 	<CSF Label Name>	<CSF Value Value>	<CSF Value ExtraValue>
 
+With -by-category, items of each category (the part before ":" in CSF Label Name) are exported into a separate sheet named after the category, items without a category go to the "General" sheet.
+
 This file can be imported into a CSF file later.`,
 	"import": `Usage: csfutil import <input.xlsx> <filename.csf>
 
-Import all CSF LabelValue items inside the spread sheet into the CSF file. Existing items will be overwritten and missing items will be created. The file must have the following structure:
+Import all CSF LabelValue items inside every sheet of the spread sheet into the CSF file. Existing items will be overwritten and missing items will be created. The file must have the following structure:
 
 	<CSF Label Name>	<CSF Value Value>	<CSF Value ExtraValue>
 
@@ -26,6 +29,9 @@ Otherwise the`,
 	"merge": `Usage: csfutil merge <source.csf> <destination.csf>
 
 Merges all CSF LabelValue items inside the source file into the destination file. Only existing items will be overwritten and missing items won't' be created.`,
+	"diff": `Usage: csfutil diff <a.csf> <b.csf>
+
+Compares two CSF files. Prints the count of items added in b, removed from a and changed between them, following the changed items with their old and new values.`,
 	"new": `Usage: csfutil new <filename.csf> [language code]
 
 Create a empty Version 3 csf file. Valid language code can be 0~9, otherwise it will be recognized as "Unknown".`,
@@ -40,6 +46,7 @@ The commands are:
 	export  convert a CSF file to a spreadsheet
 	import  merge items from a spreadsheet into a CSF file
 	merge   merge one CSF file into another
+	diff    compare two CSF files
 	new     create empty Version 3 CSF file
 
 Use "csfutil help <command>" for more information about a command.`,
@@ -78,6 +85,42 @@ func merge(src, dst string) error {
 	return err
 }
 
+// valuesString returns all decoded Values of lv in "Value , ExtraValue"
+// format, separated by " ; ".
+func valuesString(lv csfutil.LabelValue) string {
+	values := []string{}
+	for _, v := range lv.AllValues() {
+		s := v.ValueString()
+		if v.HaveExtra {
+			s += " , " + v.ExtraValueString()
+		}
+		values = append(values, s)
+	}
+
+	return strings.Join(values, " ; ")
+}
+
+func diff(a, b string) error {
+	aFile, err := csfutil.Open(a)
+	if err != nil {
+		return fmt.Errorf("%s: %w", a, err)
+	}
+	bFile, err := csfutil.Open(b)
+	if err != nil {
+		return fmt.Errorf("%s: %w", b, err)
+	}
+
+	result := csfutil.Diff(aFile, bFile)
+	fmt.Printf("added: %d, removed: %d, changed: %d\n", len(result.OnlyInB), len(result.OnlyInA), len(result.Changed))
+	for _, c := range result.Changed {
+		fmt.Println(c.Label)
+		fmt.Println("\t-", valuesString(c.Old))
+		fmt.Println("\t+", valuesString(c.New))
+	}
+
+	return nil
+}
+
 func main() {
 	argCount := len(os.Args)
 	if argCount < 3 {
@@ -90,11 +133,17 @@ func main() {
 	} else {
 		switch os.Args[1] {
 		case "export":
-			if argCount == 3 || argCount == 4 {
-				input := os.Args[2]
+			args := os.Args[2:]
+			byCategory := args[0] == "-by-category"
+			if byCategory {
+				args = args[1:]
+			}
+
+			if len(args) == 1 || len(args) == 2 {
+				input := args[0]
 				output := "output.xlsx"
-				if argCount == 4 {
-					output = os.Args[3]
+				if len(args) == 2 {
+					output = args[1]
 				}
 
 				csf, err := csfutil.Open(input)
@@ -103,13 +152,17 @@ func main() {
 					return
 				}
 
-				err = csfutil.ExportExcel(csf, output)
+				if byCategory {
+					err = csfutil.ExportExcelByCategory(csf, output)
+				} else {
+					err = csfutil.ExportExcel(csf, output)
+				}
 				if err != nil {
 					printError(err)
 					return
 				}
 			} else {
-				fmt.Println("Incorrect argument count, want 3 or 4, got", argCount)
+				fmt.Println("Incorrect file argument count, want 1 or 2, got", len(args))
 				help(os.Args[1])
 			}
 		case "import":
@@ -136,6 +189,17 @@ func main() {
 				fmt.Println("Incorrect argument count, want 4, got", argCount)
 				help(os.Args[1])
 			}
+		case "diff":
+			if argCount == 4 {
+				err := diff(os.Args[2], os.Args[3])
+				if err != nil {
+					printError(err)
+					return
+				}
+			} else {
+				fmt.Println("Incorrect argument count, want 4, got", argCount)
+				help(os.Args[1])
+			}
 		case "new":
 			if argCount == 3 || argCount == 4 {
 				output := os.Args[2]
@@ -7,20 +7,22 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
 	"strings"
 )
 
 // A CSFUtil provides some basic methods for accessing a CSF file.
 // The zero value of CSFUtil cannot be used and may cause nil pointer panic,
-// always use Open() to open a file.
+// always use Open(), OpenReader() or Parse() to obtain one.
 type CSFUtil struct {
 	dword []byte // buffer for read uint values
 
-	// file holds a temporary reference to the underlying CSF file while
+	// file holds a temporary reference to the underlying CSF data while
 	// reading contents.
-	file *os.File
+	file io.ReadSeeker
 
-	// name of currently opening file
+	// name of currently opening file, empty if CSFUtil is not opened from a
+	// file
 	filename string
 
 	Version    uint // CSF file Version section
@@ -29,6 +31,12 @@ type CSFUtil struct {
 	Unused     uint // CSF file (unused) section
 	Language   uint // CSF file Language section
 
+	// maxLabels limits how many CSF Labels can be read, 0 means unlimited.
+	maxLabels uint
+	// numStrings holds the count of all CSF Values in Values, NumStrings is
+	// set to it whenever Values changes.
+	numStrings uint
+
 	// Values holds all CSF LabelValue pair, uses capitalized CSF Label name
 	// as map key
 	Values map[string]LabelValue
@@ -224,6 +232,25 @@ func (r *CSFUtil) readValue() (Value, error) {
 	return val, nil
 }
 
+// readValues reads the following n CSF Values and returns.
+func (r *CSFUtil) readValues(n uint) ([]Value, error) {
+	// n comes from the file, don't trust it for preallocation
+	capacity := n
+	if capacity > 16 {
+		capacity = 16
+	}
+	values := make([]Value, 0, capacity)
+	for i := uint(0); i < n; i++ {
+		value, err := r.readValue()
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+
+	return values, nil
+}
+
 // readContent reads the CSF file till end and store all parsed
 // CSF LabelValue pairs into Values map.
 func (r *CSFUtil) readContent() error {
@@ -231,7 +258,7 @@ func (r *CSFUtil) readContent() error {
 	mapCate := map[string][]string{}
 	listOrder := []string{}
 
-	counter := 0
+	var counter uint
 	for {
 		label, err := r.readLabel()
 		if err != nil {
@@ -242,7 +269,7 @@ func (r *CSFUtil) readContent() error {
 			}
 		}
 
-		value, err := r.readValue()
+		values, err := r.readValues(label.StringPairs)
 		if err != nil {
 			if errors.Is(err, io.EOF) {
 				break
@@ -261,25 +288,29 @@ func (r *CSFUtil) readContent() error {
 				if list, ok := mapCate[categoryName]; ok {
 					mapCate[categoryName] = append(list, labelName)
 				} else {
-					mapCate[categoryName] = []string{}
+					mapCate[categoryName] = []string{labelName}
 				}
 			} else {
 				if list, ok := mapCate[""]; ok {
 					mapCate[""] = append(list, labelName)
 				} else {
-					mapCate[""] = []string{}
+					mapCate[""] = []string{labelName}
 				}
 			}
 
 			listOrder = append(listOrder, upperLabelName)
 		}
 
-		lv := LabelValue{Label: label, Value: value}
+		lv := LabelValue{Label: label, NoValue: len(values) == 0}
+		if len(values) > 0 {
+			lv.Value = values[0]
+			lv.AdditionalValues = values[1:]
+		}
 		mapLabelValue[upperLabelName] = lv
 
 		counter++
-		if counter > 20000 {
-			return fmt.Errorf("too many strings")
+		if r.maxLabels > 0 && counter > r.maxLabels {
+			return fmt.Errorf("too many labels, limit is %d", r.maxLabels)
 		}
 	}
 
@@ -287,6 +318,12 @@ func (r *CSFUtil) readContent() error {
 	r.Categories = mapCate
 	r.Order = listOrder
 
+	// keep NumStrings from file header untouched until Values changes
+	r.numStrings = 0
+	for _, lv := range mapLabelValue {
+		r.numStrings += lv.numStrings()
+	}
+
 	return nil
 }
 
@@ -299,9 +336,15 @@ func (r *CSFUtil) openAndParse(name string) error {
 	}
 	defer f.Close()
 
+	return r.parse(f)
+}
+
+// parse reads and parses CSF data from rs.
+func (r *CSFUtil) parse(rs io.ReadSeeker) error {
 	// initialization
-	r.file = f
+	r.file = rs
 	r.dword = make([]byte, 4)
+	defer func() { r.file = nil }()
 
 	// read file as ModEnc suggests
 	if err := r.readHeader(); err != nil {
@@ -355,21 +398,31 @@ func (r *CSFUtil) WriteLabelValueBefore(lv LabelValue, overwriteLabel bool, succ
 	} else if _, ok := r.Values[successorUpper]; ok {
 		// new in the middle
 		pos := len(r.Order)
-		l := pos
 		for i, s := range r.Order {
 			if s == successorUpper {
 				pos = i
+				break
 			}
 		}
-		r.Order = append(append(r.Order[0:pos], labelNameUpper), r.Order[pos:l]...)
+		// build a new slice, appending onto r.Order[0:pos] would overwrite
+		// the elements after pos before they are copied
+		order := make([]string, len(r.Order)+1)
+		copy(order, r.Order[0:pos])
+		order[pos] = labelNameUpper
+		copy(order[pos+1:], r.Order[pos:])
+		r.Order = order
 	} else {
 		// new at the end
 		r.Order = append(r.Order, labelNameUpper)
 	}
+	if old, ok := r.Values[labelNameUpper]; ok {
+		r.numStrings -= old.numStrings()
+	}
 	r.Values[labelNameUpper] = lv
+	r.numStrings += lv.numStrings()
 
 	r.NumLabels = uint(len(r.Values))
-	r.NumStrings = uint(len(r.Values))
+	r.NumStrings = r.numStrings
 }
 
 // RemoveLabelValue is used to eliminate the given CSF LabelValue
@@ -378,6 +431,9 @@ func (r *CSFUtil) RemoveLabelValue(name string) {
 		return
 	} else {
 		upperName := strings.ToUpper(name)
+		if old, ok := r.Values[upperName]; ok {
+			r.numStrings -= old.numStrings()
+		}
 		delete(r.Values, upperName)
 		for i, s := range r.Order {
 			if s == upperName {
@@ -390,18 +446,12 @@ func (r *CSFUtil) RemoveLabelValue(name string) {
 			}
 		}
 		r.NumLabels = uint(len(r.Values))
-		r.NumStrings = uint(len(r.Values))
+		r.NumStrings = r.numStrings
 	}
 }
 
-// Save saves the changes to the CSF file. Intermediate backup file will be
-// created as $tmp_filename.csf.
-func (r *CSFUtil) Save() error {
-	f, err := os.OpenFile("$tmp_"+r.filename, os.O_WRONLY|os.O_CREATE, 0644)
-	if err != nil {
-		return err
-	}
-
+// WriteTo writes CSF file header and all CSF LabelValue pairs in Order to w.
+func (r *CSFUtil) WriteTo(w io.Writer) (int64, error) {
 	b := []byte{}
 	buf := bytes.NewBuffer(b)
 	dword := make([]byte, 4)
@@ -423,35 +473,106 @@ func (r *CSFUtil) Save() error {
 		buf.Write(r.Values[s].Bytes())
 	}
 
-	_, err = f.Write(buf.Bytes())
+	return buf.WriteTo(w)
+}
+
+// SaveAs saves the CSF file as the named file. Intermediate backup file will
+// be created as $tmp_filename.csf in the same directory, and removed if any
+// error occurs while writing or renaming it.
+func (r *CSFUtil) SaveAs(name string) error {
+	tmpName := filepath.Join(filepath.Dir(name), "$tmp_"+filepath.Base(name))
+	f, err := os.OpenFile(tmpName, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
 	if err != nil {
 		return err
 	}
 
-	err = f.Sync()
+	_, err = r.WriteTo(f)
+	if err == nil {
+		err = f.Sync()
+	}
 	if err != nil {
+		f.Close()
+		os.Remove(tmpName)
 		return err
 	}
 
-	f.Close()
+	if err := f.Close(); err != nil {
+		os.Remove(tmpName)
+		return err
+	}
 
-	if err := os.Rename("$tmp_"+r.filename, r.filename); err != nil {
+	if err := os.Rename(tmpName, name); err != nil {
+		os.Remove(tmpName)
 		return err
 	}
 
 	return nil
 }
 
+// Save saves the changes to the CSF file, see SaveAs(). A CSFUtil obtained
+// from OpenReader() or Parse() has no underlying file and cannot be saved,
+// use SaveAs() or WriteTo() instead.
+func (r *CSFUtil) Save() error {
+	if r.filename == "" {
+		return fmt.Errorf("no file to save to, CSFUtil is not opened from a file")
+	}
+
+	return r.SaveAs(r.filename)
+}
+
+// An Option configures how a CSFUtil reads CSF data, pass it to Open(),
+// MustOpen(), OpenReader() or Parse().
+type Option func(*CSFUtil)
+
+// WithMaxLabels makes reading fail if the data contains more than limit CSF
+// Label records. Duplicate CSF Labels are counted each time they appear. A
+// limit of 0 means unlimited, which is the default.
+func WithMaxLabels(limit uint) Option {
+	return func(r *CSFUtil) {
+		r.maxLabels = limit
+	}
+}
+
+// newWithOptions returns a CSFUtil for reading with opts applied.
+func newWithOptions(name string, opts []Option) *CSFUtil {
+	reader := &CSFUtil{filename: name}
+	for _, opt := range opts {
+		opt(reader)
+	}
+
+	return reader
+}
+
 // Open opens the given file and parse it, returning pointer to CSFUtil.
 // Remember to call Close() on the returning CSFUtil.
-func Open(name string) (*CSFUtil, error) {
-	reader := &CSFUtil{filename: name}
+func Open(name string, opts ...Option) (*CSFUtil, error) {
+	reader := newWithOptions(name, opts)
 	return reader, reader.openAndParse(name)
 }
 
+// OpenReader reads all CSF data from r and parse it, returning pointer to
+// CSFUtil. The returning CSFUtil is not associated with any file, so Save()
+// will always fail, use SaveAs() or WriteTo() instead.
+func OpenReader(r io.Reader, opts ...Option) (*CSFUtil, error) {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return Parse(b, opts...)
+}
+
+// Parse parses the given CSF data, returning pointer to CSFUtil. The
+// returning CSFUtil is not associated with any file, so Save() will always
+// fail, use SaveAs() or WriteTo() instead.
+func Parse(b []byte, opts ...Option) (*CSFUtil, error) {
+	reader := newWithOptions("", opts)
+	return reader, reader.parse(bytes.NewReader(b))
+}
+
 // MustOpen do the same thing as Open, but panics if error occurs.
-func MustOpen(name string) *CSFUtil {
-	u, err := Open(name)
+func MustOpen(name string, opts ...Option) *CSFUtil {
+	u, err := Open(name, opts...)
 	if err != nil {
 		panic(err)
 	}
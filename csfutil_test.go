@@ -0,0 +1,436 @@
+package csfutil
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+// testValue holds a Value and its optional ExtraValue for building test data.
+type testValue struct {
+	value string
+	extra string
+}
+
+// testLabel holds a Label name and its Values for building test data.
+type testLabel struct {
+	name   string
+	values []testValue
+}
+
+// dword returns the little endian binary form of n.
+func dword(n int) []byte {
+	b := make([]byte, 4)
+	binary.LittleEndian.PutUint32(b, uint32(n))
+	return b
+}
+
+// buildCSF builds a Version 3 CSF file containing the given labels, the header
+// counts are computed from labels.
+func buildCSF(labels ...testLabel) []byte {
+	numStrings := 0
+	for _, l := range labels {
+		numStrings += len(l.values)
+	}
+
+	buf := bytes.Buffer{}
+	buf.WriteString(CSFFileIdentifier)
+	buf.Write(dword(3))
+	buf.Write(dword(len(labels)))
+	buf.Write(dword(numStrings))
+	buf.Write(dword(0))
+	buf.Write(dword(0))
+
+	for _, l := range labels {
+		buf.WriteString(LabelIdentifier)
+		buf.Write(dword(len(l.values)))
+		buf.Write(dword(len(l.name)))
+		buf.WriteString(l.name)
+
+		for _, tv := range l.values {
+			v := Value{}
+			v.Write(tv.value)
+			if tv.extra != "" {
+				v.WriteExtra(tv.extra)
+			}
+			buf.Write(v.Bytes())
+		}
+	}
+
+	return buf.Bytes()
+}
+
+// mustParse parses b or fails the test.
+func mustParse(t *testing.T, b []byte) *CSFUtil {
+	t.Helper()
+
+	u, err := Parse(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return u
+}
+
+// writeBytes returns the WriteTo output of u or fails the test.
+func writeBytes(t *testing.T, u *CSFUtil) []byte {
+	t.Helper()
+
+	buf := bytes.Buffer{}
+	if _, err := u.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.Bytes()
+}
+
+func TestParseAndOpenReader(t *testing.T) {
+	b := buildCSF(
+		testLabel{"GUI:Play", []testValue{{"Play", ""}}},
+		testLabel{"VOX:ceva001", []testValue{{"Warning: Nuclear Silo detected.", "ceva001c"}}},
+	)
+
+	for name, open := range map[string]func() (*CSFUtil, error){
+		"Parse":      func() (*CSFUtil, error) { return Parse(b) },
+		"OpenReader": func() (*CSFUtil, error) { return OpenReader(bytes.NewReader(b)) },
+	} {
+		u, err := open()
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+
+		if u.Version != 3 || u.NumLabels != 2 || u.NumStrings != 2 {
+			t.Errorf("%s: wrong header, got Version %d, NumLabels %d, NumStrings %d", name, u.Version, u.NumLabels, u.NumStrings)
+		}
+
+		lv := u.Values["VOX:CEVA001"]
+		if lv.Value.ValueString() != "Warning: Nuclear Silo detected." || lv.Value.ExtraValueString() != "ceva001c" {
+			t.Errorf("%s: wrong value, got %s", name, lv)
+		}
+
+		if err := u.Save(); err == nil {
+			t.Errorf("%s: Save() without a file should fail", name)
+		}
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	if _, err := Parse([]byte("NOT A CSF FILE")); err == nil {
+		t.Error("want error parsing invalid data")
+	}
+}
+
+func TestMultiValueRoundTrip(t *testing.T) {
+	b := buildCSF(
+		testLabel{"GUI:Play", []testValue{{"Play", ""}}},
+		testLabel{"GUI:Quit", []testValue{{"Quit", "quit"}, {"Exit", ""}}},
+		testLabel{"MSG:Hello", []testValue{{"Hello", ""}}},
+	)
+
+	u := mustParse(t, b)
+	lv := u.Values["GUI:QUIT"]
+	if lv.Label.StringPairs != 2 || len(lv.AllValues()) != 2 {
+		t.Fatalf("want 2 values, got %d", len(lv.AllValues()))
+	}
+	if lv.Value.ValueString() != "Quit" || lv.AdditionalValues[0].ValueString() != "Exit" {
+		t.Errorf("wrong values, got %s", lv)
+	}
+	if u.Values["MSG:HELLO"].Value.ValueString() != "Hello" {
+		t.Errorf("label after multi-value label is corrupted, got %s", u.Values["MSG:HELLO"])
+	}
+
+	if out := writeBytes(t, u); !bytes.Equal(out, b) {
+		t.Errorf("round trip mismatch, want %d bytes, got %d bytes", len(b), len(out))
+	}
+
+	name := filepath.Join(t.TempDir(), "multi.csf")
+	if err := os.WriteFile(name, b, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := Open(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := os.ReadFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(out, b) {
+		t.Errorf("saved file mismatch, want %d bytes, got %d bytes", len(b), len(out))
+	}
+}
+
+func TestHugeStringPairs(t *testing.T) {
+	b := buildCSF(testLabel{"GUI:Play", []testValue{{"Play", ""}}})
+	// StringPairs of the first label
+	binary.LittleEndian.PutUint32(b[28:32], 0xFFFFFFFF)
+
+	// must end at EOF instead of allocating for 0xFFFFFFFF values
+	u, err := Parse(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := u.Values["GUI:PLAY"]; ok {
+		t.Error("want truncated label dropped")
+	}
+}
+
+func TestZeroValueRoundTrip(t *testing.T) {
+	b := buildCSF(
+		testLabel{"GUI:Empty", nil},
+		testLabel{"GUI:Play", []testValue{{"Play", ""}}},
+	)
+
+	u := mustParse(t, b)
+	if lv := u.Values["GUI:EMPTY"]; !lv.NoValue || len(lv.AllValues()) != 0 {
+		t.Errorf("want no value, got %s", lv)
+	}
+
+	if out := writeBytes(t, u); !bytes.Equal(out, b) {
+		t.Errorf("round trip mismatch, want %d bytes, got %d bytes", len(b), len(out))
+	}
+}
+
+func TestCategories(t *testing.T) {
+	u := mustParse(t, buildCSF(
+		testLabel{"GUI:Play", []testValue{{"Play", ""}}},
+		testLabel{"GUI:Quit", []testValue{{"Quit", ""}}},
+		testLabel{"MSG:Hello", []testValue{{"Hello", ""}}},
+		testLabel{"Plain", []testValue{{"Plain", ""}}},
+	))
+
+	want := map[string][]string{
+		"GUI": {"GUI:Play", "GUI:Quit"},
+		"MSG": {"MSG:Hello"},
+		"":    {"Plain"},
+	}
+	if len(u.Categories) != len(want) {
+		t.Errorf("want %d categories, got %v", len(want), u.Categories)
+	}
+	for name, labels := range want {
+		if !equalStrings(u.Categories[name], labels) {
+			t.Errorf("category %q: want %v, got %v", name, labels, u.Categories[name])
+		}
+	}
+}
+
+// equalStrings reports whether a and b have the same elements in the same
+// order.
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func TestWriteLabelValueBefore(t *testing.T) {
+	b := buildCSF(
+		testLabel{"A:1", []testValue{{"1", ""}}},
+		testLabel{"A:2", []testValue{{"2", ""}}},
+		testLabel{"A:3", []testValue{{"3", ""}}},
+	)
+
+	tests := []struct {
+		name      string
+		successor string
+		want      []string
+	}{
+		{"middle", "a:2", []string{"A:1", "NEW:X", "A:2", "A:3"}},
+		{"front", "A:1", []string{"NEW:X", "A:1", "A:2", "A:3"}},
+		{"missing successor", "A:404", []string{"A:1", "A:2", "A:3", "NEW:X"}},
+	}
+
+	for _, tt := range tests {
+		u := mustParse(t, b)
+		u.WriteLabelValueBefore(NewLabelValue("New:x", "x"), false, tt.successor)
+		if !equalStrings(u.Order, tt.want) {
+			t.Errorf("%s: want %v, got %v", tt.name, tt.want, u.Order)
+		}
+	}
+
+	// several inserts in a row must not clobber each other
+	u := mustParse(t, b)
+	u.WriteLabelValueBefore(NewLabelValue("New:x", "x"), false, "A:2")
+	u.WriteLabelValueBefore(NewLabelValue("New:y", "y"), false, "A:2")
+	u.WriteLabelValueBefore(NewLabelValue("New:z", "z"), false, "A:3")
+	want := []string{"A:1", "NEW:X", "NEW:Y", "A:2", "NEW:Z", "A:3"}
+	if !equalStrings(u.Order, want) {
+		t.Errorf("several inserts: want %v, got %v", want, u.Order)
+	}
+}
+
+func TestManyLabels(t *testing.T) {
+	labels := make([]testLabel, 25000)
+	for i := range labels {
+		labels[i] = testLabel{"L:" + strconv.Itoa(i), []testValue{{"v", ""}}}
+	}
+	b := buildCSF(labels...)
+
+	u := mustParse(t, b)
+	if len(u.Order) != len(labels) || u.NumLabels != uint(len(labels)) {
+		t.Errorf("want %d labels, got %d", len(labels), len(u.Order))
+	}
+
+	if _, err := Parse(b, WithMaxLabels(20000)); err == nil {
+		t.Error("Parse: want error exceeding the label limit")
+	}
+
+	if _, err := OpenReader(bytes.NewReader(b), WithMaxLabels(20000)); err == nil {
+		t.Error("OpenReader: want error exceeding the label limit")
+	}
+
+	name := filepath.Join(t.TempDir(), "many.csf")
+	if err := u.SaveAs(name); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Open(name, WithMaxLabels(20000)); err == nil {
+		t.Error("Open: want error exceeding the label limit")
+	}
+	if _, err := Open(name, WithMaxLabels(25000)); err != nil {
+		t.Errorf("Open: want no error within the label limit, got %v", err)
+	}
+}
+
+func TestNumStrings(t *testing.T) {
+	u := mustParse(t, buildCSF(
+		testLabel{"GUI:Empty", nil},
+		testLabel{"GUI:Play", []testValue{{"Play", ""}}},
+	))
+
+	lv := NewLabelValue("GUI:Quit", "Quit")
+	lv.AdditionalValues = []Value{lv.Value, lv.Value}
+	u.WriteLabelValue(lv, false)
+	if u.NumLabels != 3 || u.NumStrings != 4 {
+		t.Errorf("want 3 labels and 4 strings, got %d and %d", u.NumLabels, u.NumStrings)
+	}
+
+	u.WriteLabelValue(NewLabelValue("GUI:Quit", "Quit"), false)
+	if u.NumLabels != 3 || u.NumStrings != 2 {
+		t.Errorf("overwrite: want 3 labels and 2 strings, got %d and %d", u.NumLabels, u.NumStrings)
+	}
+
+	for _, s := range []string{"GUI:Empty", "GUI:Play", "GUI:Quit"} {
+		u.RemoveLabelValue(s)
+	}
+	if u.NumLabels != 0 || u.NumStrings != 0 {
+		t.Errorf("remove: want 0 labels and 0 strings, got %d and %d", u.NumLabels, u.NumStrings)
+	}
+}
+
+func TestNumStringsOverwrite(t *testing.T) {
+	u := mustParse(t, buildCSF(
+		testLabel{"GUI:Quit", []testValue{{"Quit", ""}, {"Exit", ""}, {"Leave", "leave"}}},
+		testLabel{"GUI:Play", []testValue{{"Play", ""}}},
+	))
+	if u.NumStrings != 4 {
+		t.Fatalf("want 4 strings, got %d", u.NumStrings)
+	}
+
+	u.WriteLabelValue(NewLabelValue("gui:quit", "Quit"), false)
+	if u.NumLabels != 2 || u.NumStrings != 2 {
+		t.Errorf("want 2 labels and 2 strings, got %d and %d", u.NumLabels, u.NumStrings)
+	}
+	if got := writeBytes(t, u); len(got) < 20 || binary.LittleEndian.Uint32(got[12:16]) != 2 {
+		t.Errorf("want NumStrings 2 in saved header")
+	}
+
+	// counts stay right over a bulk import
+	for i := 0; i < 30000; i++ {
+		u.WriteLabelValue(NewLabelValue("L:"+strconv.Itoa(i), "v"), false)
+	}
+	if u.NumLabels != 30002 || u.NumStrings != 30002 {
+		t.Errorf("want 30002 labels and 30002 strings, got %d and %d", u.NumLabels, u.NumStrings)
+	}
+}
+
+func TestWriteTo(t *testing.T) {
+	u := New("", 3, 0, 9)
+	u.WriteLabelValue(NewLabelValue("A:b", "Hi", "x"), false)
+
+	want := []byte{
+		' ', 'F', 'S', 'C', // file identifier
+		3, 0, 0, 0, // version
+		1, 0, 0, 0, // num labels
+		1, 0, 0, 0, // num strings
+		0, 0, 0, 0, // unused
+		9, 0, 0, 0, // language
+		' ', 'L', 'B', 'L', // label identifier
+		1, 0, 0, 0, // string pairs
+		3, 0, 0, 0, // label length
+		'A', ':', 'b', // label name
+		'W', 'R', 'T', 'S', // value with extra identifier
+		2, 0, 0, 0, // value length in UTF-16 code units
+		^byte('H'), ^byte(0), ^byte('i'), ^byte(0), // value, NOT of UTF-16
+		1, 0, 0, 0, // extra value length
+		'x', // extra value
+	}
+
+	buf := bytes.Buffer{}
+	n, err := u.WriteTo(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != int64(len(want)) || !bytes.Equal(buf.Bytes(), want) {
+		t.Errorf("want %d bytes % x, got %d bytes % x", len(want), want, n, buf.Bytes())
+	}
+}
+
+// tmpFiles returns the intermediate files left in dir.
+func tmpFiles(t *testing.T, dir string) []string {
+	t.Helper()
+
+	names, err := filepath.Glob(filepath.Join(dir, "$tmp_*"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return names
+}
+
+func TestSaveAs(t *testing.T) {
+	dir := t.TempDir()
+	u := New("", 3, 0, 0)
+	u.WriteLabelValue(NewLabelValue("A:b", "Hi"), false)
+
+	name := filepath.Join(dir, "output.csf")
+	if err := u.SaveAs(name); err != nil {
+		t.Fatal(err)
+	}
+	if names := tmpFiles(t, dir); len(names) != 0 {
+		t.Errorf("want no intermediate file, got %v", names)
+	}
+
+	saved, err := Open(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(writeBytes(t, saved), writeBytes(t, u)) {
+		t.Error("saved file mismatch")
+	}
+
+	// renaming onto a non-empty directory fails
+	name = filepath.Join(dir, "dir.csf")
+	if err := os.MkdirAll(filepath.Join(name, "child"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := u.SaveAs(name); err == nil {
+		t.Error("want error saving onto a directory")
+	}
+	if names := tmpFiles(t, dir); len(names) != 0 {
+		t.Errorf("want no intermediate file after error, got %v", names)
+	}
+}
@@ -0,0 +1,64 @@
+package csfutil
+
+// DiffChange holds a CSF Label presented in both files but with different
+// Values.
+type DiffChange struct {
+	Label string     // capitalized CSF Label name
+	Old   LabelValue // LabelValue pair in A
+	New   LabelValue // LabelValue pair in B
+}
+
+// DiffResult holds the differences between two CSF files. All CSF Label names
+// are in capitalized form.
+type DiffResult struct {
+	OnlyInA []string     // CSF Labels only in A, in A's order
+	OnlyInB []string     // CSF Labels only in B, in B's order
+	Changed []DiffChange // CSF Labels with different Values, in A's order
+}
+
+// valuesEqual reports whether x and y have the same decoded Values and
+// ExtraValues.
+func valuesEqual(x, y LabelValue) bool {
+	xs, ys := x.AllValues(), y.AllValues()
+	if len(xs) != len(ys) {
+		return false
+	}
+
+	for i := range xs {
+		if xs[i].HaveExtra != ys[i].HaveExtra ||
+			xs[i].ValueString() != ys[i].ValueString() ||
+			xs[i].ExtraValueString() != ys[i].ExtraValueString() {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Diff compares the CSF LabelValue pairs of a and b. Values are compared by
+// their decoded strings, the Label name case is ignored.
+func Diff(a, b *CSFUtil) *DiffResult {
+	result := &DiffResult{
+		OnlyInA: []string{},
+		OnlyInB: []string{},
+		Changed: []DiffChange{},
+	}
+
+	for _, s := range a.Order {
+		va := a.Values[s]
+		vb, ok := b.Values[s]
+		if !ok {
+			result.OnlyInA = append(result.OnlyInA, s)
+		} else if !valuesEqual(va, vb) {
+			result.Changed = append(result.Changed, DiffChange{Label: s, Old: va, New: vb})
+		}
+	}
+
+	for _, s := range b.Order {
+		if _, ok := a.Values[s]; !ok {
+			result.OnlyInB = append(result.OnlyInB, s)
+		}
+	}
+
+	return result
+}
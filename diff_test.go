@@ -0,0 +1,76 @@
+package csfutil
+
+import "testing"
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		name    string
+		a, b    testLabel
+		onlyInA []string
+		onlyInB []string
+		changed []string
+	}{
+		{
+			name:    "only in A",
+			a:       testLabel{"GUI:Removed", []testValue{{"Removed", ""}}},
+			b:       testLabel{"GUI:Other", []testValue{{"Other", ""}}},
+			onlyInA: []string{"GUI:REMOVED"},
+			onlyInB: []string{"GUI:OTHER"},
+		},
+		{
+			name:    "only in B",
+			a:       testLabel{"GUI:Play", []testValue{{"Play", ""}}},
+			b:       testLabel{"GUI:Added", []testValue{{"Added", ""}}},
+			onlyInA: []string{"GUI:PLAY"},
+			onlyInB: []string{"GUI:ADDED"},
+		},
+		{
+			name: "label case only",
+			a:    testLabel{"gui:case", []testValue{{"Case", "case"}}},
+			b:    testLabel{"GUI:Case", []testValue{{"Case", "case"}}},
+		},
+		{
+			name:    "different value",
+			a:       testLabel{"GUI:Value", []testValue{{"Old", ""}}},
+			b:       testLabel{"GUI:Value", []testValue{{"New", ""}}},
+			changed: []string{"GUI:VALUE"},
+		},
+		{
+			name:    "different extra value",
+			a:       testLabel{"GUI:Extra", []testValue{{"Extra", "old"}}},
+			b:       testLabel{"GUI:Extra", []testValue{{"Extra", "new"}}},
+			changed: []string{"GUI:EXTRA"},
+		},
+		{
+			name:    "different pair count",
+			a:       testLabel{"GUI:Pairs", []testValue{{"One", ""}}},
+			b:       testLabel{"GUI:Pairs", []testValue{{"One", ""}, {"Two", ""}}},
+			changed: []string{"GUI:PAIRS"},
+		},
+	}
+
+	for _, tt := range tests {
+		shared := testLabel{"GUI:Shared", []testValue{{"Shared", ""}}}
+		a := mustParse(t, buildCSF(shared, tt.a))
+		b := mustParse(t, buildCSF(tt.b, shared))
+		result := Diff(a, b)
+
+		changed := []string{}
+		for _, c := range result.Changed {
+			changed = append(changed, c.Label)
+			if c.Old.String() != a.Values[c.Label].String() || c.New.String() != b.Values[c.Label].String() {
+				t.Errorf("%s: want old %s and new %s, got %s and %s", tt.name, a.Values[c.Label], b.Values[c.Label], c.Old, c.New)
+			}
+		}
+
+		if !equalStrings(result.OnlyInA, tt.onlyInA) {
+			t.Errorf("%s: want only in A %v, got %v", tt.name, tt.onlyInA, result.OnlyInA)
+		}
+		if !equalStrings(result.OnlyInB, tt.onlyInB) {
+			t.Errorf("%s: want only in B %v, got %v", tt.name, tt.onlyInB, result.OnlyInB)
+		}
+		if !equalStrings(changed, tt.changed) {
+			t.Errorf("%s: want changed %v, got %v", tt.name, tt.changed, changed)
+		}
+	}
+}
@@ -4,40 +4,151 @@ import (
 	"fmt"
 	"github.com/xuri/excelize/v2"
 	"strconv"
+	"strings"
 )
 
 const (
 	sheet            = "Sheet1"
+	generalSheet     = "General" // sheet for CSF Labels without a category
 	labelColumn      = "A"
 	valueColumn      = "B"
 	extraValueColumn = "C"
 )
 
+// writeRow writes lv into the given row of the named sheet.
+func writeRow(f *excelize.File, sheetName string, row int, lv LabelValue) error {
+	r := strconv.Itoa(row)
+
+	if err := f.SetCellValue(sheetName, labelColumn+r, lv.Label.ValueString()); err != nil {
+		return err
+	}
+
+	if err := f.SetCellValue(sheetName, valueColumn+r, lv.Value.ValueString()); err != nil {
+		return err
+	}
+
+	if lv.Value.HaveExtra {
+		if err := f.SetCellValue(sheetName, extraValueColumn+r, lv.Value.ExtraValueString()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ExportExcel writes all CSF LabelValue pairs of csf into output following
+// csf.Order, one row for each CSF Label. Only the first Value of CSF Labels
+// having more than one string pair is exported.
 func ExportExcel(csf *CSFUtil, output string) error {
 	f := excelize.NewFile()
 	defer f.Close()
 
 	for i := 0; i < len(csf.Order); i++ {
-		row := strconv.Itoa(i + 1)
-
-		if err := f.SetCellValue(sheet, labelColumn+row, csf.Values[csf.Order[i]].Label.ValueString()); err != nil {
+		if err := writeRow(f, sheet, i+1, csf.Values[csf.Order[i]]); err != nil {
 			return err
 		}
+	}
 
-		if err := f.SetCellValue(sheet, valueColumn+row, csf.Values[csf.Order[i]].Value.ValueString()); err != nil {
-			return err
+	return f.SaveAs(output)
+}
+
+// maxSheetNameLength is the maximum length of an Excel sheet name in runes.
+const maxSheetNameLength = 31
+
+// truncateRunes returns the first n runes of s.
+func truncateRunes(s string, n int) string {
+	r := []rune(s)
+	if len(r) > n {
+		return string(r[:n])
+	}
+	return s
+}
+
+// categorySheetName returns a valid sheet name for the category of the named
+// CSF Label, "General" is returned if the CSF Label has no category or the
+// category name is empty.
+func categorySheetName(labelName string) string {
+	i := strings.Index(labelName, ":")
+	if i < 0 {
+		return generalSheet
+	}
+
+	// remove characters not allowed in sheet names
+	name := strings.Map(func(r rune) rune {
+		if strings.ContainsRune(":\\/?*[]", r) {
+			return -1
 		}
+		return r
+	}, labelName[0:i])
+	name = strings.Trim(truncateRunes(name, maxSheetNameLength), "'")
+	if name == "" {
+		return generalSheet
+	}
 
-		if csf.Values[csf.Order[i]].Value.HaveExtra {
-			if err := f.SetCellValue(sheet, extraValueColumn+row, csf.Values[csf.Order[i]].Value.ExtraValueString()); err != nil {
-				return err
-			}
+	return name
+}
+
+// uniqueSheetName returns name if it is not in used, otherwise appends a
+// " (n)" suffix to it. Excel sheet names are case-insensitive, so used uses
+// capitalized sheet name as map key.
+func uniqueSheetName(name string, used map[string]bool) string {
+	unique := name
+	for n := 2; used[strings.ToUpper(unique)]; n++ {
+		suffix := " (" + strconv.Itoa(n) + ")"
+		unique = truncateRunes(name, maxSheetNameLength-len(suffix)) + suffix
+	}
+	used[strings.ToUpper(unique)] = true
+
+	return unique
+}
+
+// ExportExcelByCategory does the same as ExportExcel does, but writes the CSF
+// Labels of each category into a separate sheet named after the category.
+// CSF Labels without a category are written into the "General" sheet.
+// ImportExcel reads all sheets back.
+func ExportExcelByCategory(csf *CSFUtil, output string) error {
+	f := excelize.NewFile()
+	defer f.Close()
+
+	// sheets holds the sheet name of each category, uses capitalized category
+	// name as map key, "" for CSF Labels without a category
+	sheets := map[string]string{}
+	used := map[string]bool{}
+	// rows holds the last written row of each sheet
+	rows := map[string]int{}
+	for _, s := range csf.Order {
+		lv := csf.Values[s]
+
+		category := ""
+		if strings.Contains(s, ":") {
+			category = s[0:strings.Index(s, ":")]
+		}
+
+		sheetName, ok := sheets[category]
+		if !ok {
+			sheetName = uniqueSheetName(categorySheetName(lv.Label.ValueString()), used)
+			sheets[category] = sheetName
+			f.NewSheet(sheetName)
+		}
+
+		rows[sheetName]++
+		if err := writeRow(f, sheetName, rows[sheetName], lv); err != nil {
+			return err
 		}
 	}
 
+	if !used[strings.ToUpper(sheet)] && len(rows) > 0 {
+		f.DeleteSheet(sheet)
+		f.SetActiveSheet(0)
+	}
+
 	return f.SaveAs(output)
 }
 
+// ImportExcel writes all rows of every sheet in input into the CSF file output.
+// Existing items will be overwritten and missing items will be created. As a
+// spreadsheet only holds the first Value of an item, AdditionalValues of
+// existing items are kept.
 func ImportExcel(input, output string) error {
 	f, err := excelize.OpenFile(input)
 	if err != nil {
@@ -50,27 +161,38 @@ func ImportExcel(input, output string) error {
 		return err
 	}
 
-	rows, err := f.GetRows(sheet)
-	if err != nil {
-		return err
-	}
+	count := 0
+	for _, sheetName := range f.GetSheetList() {
+		rows, err := f.GetRows(sheetName)
+		if err != nil {
+			return err
+		}
+		count += len(rows)
 
-	if len(rows) < 1 {
-		return fmt.Errorf("no data to read")
-	}
+		for i, row := range rows {
+			var lv LabelValue
+			switch len(row) {
+			case 1:
+				lv = NewLabelValue(row[0], "")
+			case 2:
+				lv = NewLabelValue(row[0], row[1])
+			case 3:
+				lv = NewLabelValue(row[0], row[1], row[2])
+			default:
+				return fmt.Errorf("wrong column count, want 2~3, got %d, at sheet: %s, row: %d", len(row), sheetName, i)
+			}
 
-	for i, row := range rows {
-		switch len(row) {
-		case 1:
-			csf.WriteLabelValue(NewLabelValue(row[0], ""), false)
-		case 2:
-			csf.WriteLabelValue(NewLabelValue(row[0], row[1]), false)
-		case 3:
-			csf.WriteLabelValue(NewLabelValue(row[0], row[1], row[2]), false)
-		default:
-			return fmt.Errorf("wrong column count, want 2~3, got %d, at row: %d", len(row), i)
+			// spreadsheets only hold the first Value, keep the rest ones
+			if old, ok := csf.Values[strings.ToUpper(row[0])]; ok && !old.NoValue {
+				lv.AdditionalValues = old.AdditionalValues
+			}
+			csf.WriteLabelValue(lv, false)
 		}
 	}
 
+	if count < 1 {
+		return fmt.Errorf("no data to read")
+	}
+
 	return csf.Save()
 }
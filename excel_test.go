@@ -0,0 +1,116 @@
+package csfutil
+
+import (
+	"github.com/xuri/excelize/v2"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExportExcelByCategory(t *testing.T) {
+	dir := t.TempDir()
+	xlsxFile := filepath.Join(dir, "output.xlsx")
+	csfFile := filepath.Join(dir, "output.csf")
+
+	long := strings.Repeat("L", 40)
+	src := mustParse(t, buildCSF(
+		testLabel{"Gui:Play", []testValue{{"Play", ""}}},
+		testLabel{":Foo", []testValue{{"Foo", ""}}},
+		testLabel{"GUI:Quit", []testValue{{"Quit", "quit"}}},
+		testLabel{"Plain", []testValue{{"Plain", ""}}},
+		testLabel{"General:Bar", []testValue{{"Bar", ""}}},
+		testLabel{long + "1:A", []testValue{{"A", ""}}},
+		testLabel{long + "2:B", []testValue{{"B", ""}}},
+		testLabel{"[]:C", []testValue{{"C", ""}}},
+	))
+
+	if err := ExportExcelByCategory(src, xlsxFile); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := excelize.OpenFile(xlsxFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	want := []string{
+		"Gui",
+		"General",
+		"General (2)",
+		strings.Repeat("L", 31),
+		strings.Repeat("L", 27) + " (2)",
+		"General (3)",
+	}
+	if got := f.GetSheetList(); !equalStrings(got, want) {
+		t.Errorf("want sheets %q, got %q", want, got)
+	}
+
+	rows, err := f.GetRows("Gui")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 2 || rows[0][0] != "Gui:Play" || rows[1][0] != "GUI:Quit" {
+		t.Errorf("wrong rows in sheet Gui, got %q", rows)
+	}
+
+	if err := New(csfFile, 3, 0, 0).Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ImportExcel(xlsxFile, csfFile); err != nil {
+		t.Fatal(err)
+	}
+
+	dst, err := Open(csfFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, s := range src.Order {
+		if got, want := dst.Values[s].String(), src.Values[s].String(); got != want {
+			t.Errorf("want %s, got %s", want, got)
+		}
+	}
+}
+
+func TestImportExcelKeepsAdditionalValues(t *testing.T) {
+	dir := t.TempDir()
+	xlsxFile := filepath.Join(dir, "output.xlsx")
+	csfFile := filepath.Join(dir, "output.csf")
+
+	b := buildCSF(
+		testLabel{"GUI:Quit", []testValue{{"Quit", ""}, {"Exit", "exit"}}},
+		testLabel{"GUI:Play", []testValue{{"Play", ""}}},
+	)
+	if err := os.WriteFile(csfFile, b, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	src := mustParse(t, b)
+	src.WriteLabelValue(NewLabelValue("GUI:Quit", "Leave"), false)
+	if err := ExportExcel(src, xlsxFile); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ImportExcel(xlsxFile, csfFile); err != nil {
+		t.Fatal(err)
+	}
+
+	dst, err := Open(csfFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lv := dst.Values["GUI:QUIT"]
+	if lv.Value.ValueString() != "Leave" {
+		t.Errorf("want first value overwritten, got %s", lv)
+	}
+	if len(lv.AdditionalValues) != 1 || lv.AdditionalValues[0].ValueString() != "Exit" || lv.AdditionalValues[0].ExtraValueString() != "exit" {
+		t.Errorf("want additional values kept, got %s", lv)
+	}
+	if dst.NumStrings != 3 {
+		t.Errorf("want 3 strings, got %d", dst.NumStrings)
+	}
+}
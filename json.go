@@ -0,0 +1,116 @@
+package csfutil
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// jsonValue is the JSON form of a CSF Value.
+type jsonValue struct {
+	Value string  `json:"value"`
+	Extra *string `json:"extra,omitempty"`
+}
+
+// jsonItem is the JSON form of a CSF LabelValue pair. Value is omitted if the
+// CSF Label has no Value, Additional holds the rest Values of a CSF Label
+// having more than one string pair.
+type jsonItem struct {
+	Label      string      `json:"label"`
+	Value      *string     `json:"value,omitempty"`
+	Extra      *string     `json:"extra,omitempty"`
+	Additional []jsonValue `json:"additional,omitempty"`
+}
+
+// newJSONValue returns the JSON form of v.
+func newJSONValue(v Value) jsonValue {
+	jv := jsonValue{Value: v.ValueString()}
+	if v.HaveExtra {
+		extra := v.ExtraValueString()
+		jv.Extra = &extra
+	}
+	return jv
+}
+
+// csfValue returns the CSF Value form of jv.
+func (jv jsonValue) csfValue() Value {
+	v := Value{}
+	v.Write(jv.Value)
+	if jv.Extra != nil {
+		v.WriteExtra(*jv.Extra)
+	}
+	return v
+}
+
+// ExportJSON writes all CSF LabelValue pairs of csf into output as a JSON
+// array following csf.Order.
+func ExportJSON(csf *CSFUtil, output string) error {
+	items := make([]jsonItem, 0, len(csf.Order))
+	for _, s := range csf.Order {
+		lv := csf.Values[s]
+		item := jsonItem{Label: lv.Label.ValueString()}
+		if !lv.NoValue {
+			jv := newJSONValue(lv.Value)
+			item.Value = &jv.Value
+			item.Extra = jv.Extra
+			for _, v := range lv.AdditionalValues {
+				item.Additional = append(item.Additional, newJSONValue(v))
+			}
+		}
+		items = append(items, item)
+	}
+
+	f, err := os.Create(output)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	// one item per line keeps the file friendly to line-based diff tools
+	enc := json.NewEncoder(f)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(items); err != nil {
+		return err
+	}
+
+	return f.Sync()
+}
+
+// ImportJSON writes all items of the JSON file input into the CSF file output.
+// Existing items will be overwritten and missing items will be created.
+func ImportJSON(input, output string) error {
+	b, err := os.ReadFile(input)
+	if err != nil {
+		return err
+	}
+
+	var items []jsonItem
+	if err := json.Unmarshal(b, &items); err != nil {
+		return err
+	}
+
+	if len(items) < 1 {
+		return fmt.Errorf("no data to read")
+	}
+
+	csf, err := Open(output)
+	if err != nil {
+		return err
+	}
+
+	for _, item := range items {
+		lv := NewLabelValue(item.Label, "")
+		if item.Value != nil {
+			lv.Value = jsonValue{Value: *item.Value, Extra: item.Extra}.csfValue()
+			for _, jv := range item.Additional {
+				lv.AdditionalValues = append(lv.AdditionalValues, jv.csfValue())
+			}
+		} else {
+			lv.NoValue = true
+		}
+		csf.WriteLabelValue(lv, false)
+	}
+
+	return csf.Save()
+}
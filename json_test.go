@@ -0,0 +1,57 @@
+package csfutil
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+)
+
+func TestJSONRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	jsonFile := filepath.Join(dir, "output.json")
+	csfFile := filepath.Join(dir, "output.csf")
+
+	src := mustParse(t, buildCSF(
+		testLabel{"GUI:Play", []testValue{{"开始游戏", ""}}},
+		testLabel{"GUI:Quit", []testValue{{"게임 종료", "quit"}}},
+		testLabel{"MSG:Emoji", []testValue{{"核弹 😀 𠀀", ""}}},
+		testLabel{"MSG:Html", []testValue{{"<b>&</b>", ""}}},
+		testLabel{"MSG:Multi", []testValue{{"第一", "one"}, {"두 번째", ""}, {"𠀀", "three"}}},
+		testLabel{"MSG:Empty", nil},
+	))
+
+	if err := ExportJSON(src, jsonFile); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := New(csfFile, 3, 0, 0).Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ImportJSON(jsonFile, csfFile); err != nil {
+		t.Fatal(err)
+	}
+
+	dst, err := Open(csfFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !equalStrings(dst.Order, src.Order) {
+		t.Errorf("want order %v, got %v", src.Order, dst.Order)
+	}
+
+	for _, s := range src.Order {
+		want, got := src.Values[s], dst.Values[s]
+		if got.String() != want.String() {
+			t.Errorf("want %s, got %s", want, got)
+		}
+		if !bytes.Equal(got.Bytes(), want.Bytes()) {
+			t.Errorf("%s: binary form mismatch", s)
+		}
+	}
+
+	if dst.NumStrings != src.NumStrings {
+		t.Errorf("want %d strings, got %d", src.NumStrings, dst.NumStrings)
+	}
+}
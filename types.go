@@ -36,7 +36,7 @@ func (l Label) Bytes() []byte {
 	dword := make([]byte, 4)
 
 	buf.WriteString(LabelIdentifier)
-	binary.LittleEndian.PutUint32(dword, uint32(1))
+	binary.LittleEndian.PutUint32(dword, uint32(l.StringPairs))
 	buf.Write(dword)
 	binary.LittleEndian.PutUint32(dword, uint32(len(l.Value)))
 	buf.Write(dword)
@@ -125,31 +125,65 @@ func (v *Value) ClearExtra() {
 	v.ExtraValue = nil
 }
 
-// LabelValue is used to store Label-Value pair.
+// LabelValue is used to store Label-Value pair. Most CSF Labels have only one
+// Value, the rest Values of a Label having more than one string pair are
+// stored in AdditionalValues. A Label having no string pair at all is marked
+// by NoValue, Value and AdditionalValues are ignored then.
 type LabelValue struct {
-	Label Label
-	Value Value
+	Label            Label
+	Value            Value
+	AdditionalValues []Value
+	NoValue          bool
 }
 
-// String prints a Label-Value pair in "LabelName -> Value , ExtraValue" format.
+// AllValues returns Value and AdditionalValues in their original order.
+func (lv LabelValue) AllValues() []Value {
+	if lv.NoValue {
+		return []Value{}
+	}
+	return append([]Value{lv.Value}, lv.AdditionalValues...)
+}
+
+// numStrings returns the count of all Values.
+func (lv LabelValue) numStrings() uint {
+	if lv.NoValue {
+		return 0
+	}
+	return uint(1 + len(lv.AdditionalValues))
+}
+
+// String prints a Label-Value pair in "LabelName -> Value , ExtraValue" format,
+// additional values are appended in " ; Value , ExtraValue" format.
 // Mainly for debug purpose.
 func (lv LabelValue) String() string {
 	sb := strings.Builder{}
 	sb.WriteString(lv.Label.ValueString())
 	sb.WriteString(" -> ")
-	sb.WriteString(lv.Value.ValueString())
-	if lv.Value.HaveExtra {
-		sb.WriteString(" , ")
-		sb.WriteString(lv.Value.ExtraValueString())
+	for i, v := range lv.AllValues() {
+		if i > 0 {
+			sb.WriteString(" ; ")
+		}
+		sb.WriteString(v.ValueString())
+		if v.HaveExtra {
+			sb.WriteString(" , ")
+			sb.WriteString(v.ExtraValueString())
+		}
 	}
 	return sb.String()
 }
 
-// Bytes returns the binary form of a CSF Value data.
+// Bytes returns the binary form of a CSF Label-Value pair data. StringPairs of
+// the Label is always written as the count of all Values.
 func (lv LabelValue) Bytes() []byte {
-	lb := lv.Label.Bytes()
-	vb := lv.Value.Bytes()
-	return append(lb[:], vb...)
+	values := lv.AllValues()
+	l := lv.Label
+	l.StringPairs = lv.numStrings()
+
+	b := l.Bytes()
+	for _, v := range values {
+		b = append(b, v.Bytes()...)
+	}
+	return b
 }
 
 // NewLabelValue does the same as NewLabelValueWithOffset, but doesn't touch